@@ -0,0 +1,161 @@
+package oneinch
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// minimalProxyPrefix and minimalProxySuffix are the fixed bytes of the EIP-1167 minimal proxy
+// init code the factory clones for every new escrow, wrapped around the implementation address.
+var (
+	minimalProxyPrefix = common.FromHex("0x3d602d80600a3d3981f3363d3d373d3d3d363d73")
+	minimalProxySuffix = common.FromHex("0x5af43d82803e903d91602b57fd5bf3")
+)
+
+// create2Prefix is the fixed leading byte CREATE2 address derivation prepends.
+const create2Prefix = 0xff
+
+// escrowSaltArgs describes the ABI encoding of the CREATE2 salt for a new escrow:
+// keccak256(abi.encode(adapter, claimer, claimCommitment, refundCommitment, timeout1, timeout2,
+// asset, value)).
+var escrowSaltArgs = abi.Arguments{
+	{Type: mustABIType("address")},
+	{Type: mustABIType("address")},
+	{Type: mustABIType("bytes32")},
+	{Type: mustABIType("bytes32")},
+	{Type: mustABIType("uint256")},
+	{Type: mustABIType("uint256")},
+	{Type: mustABIType("address")},
+	{Type: mustABIType("uint256")},
+}
+
+// PredictEscrowAddress computes the CREATE2 address of the escrow that CreateSwap(params) would
+// deposit into, entirely in Go and without an RPC round-trip beyond the (cached) implementation
+// address fetch. This lets either party verify offline that a counterparty's claimed escrow
+// address actually matches the agreed-upon swap params before funding anything.
+func (c *XMREscrowClient) PredictEscrowAddress(ctx context.Context, params SwapParams) (common.Address, error) {
+	return predictEscrowAddress(ctx, c.implCache, c.client, c.escrowFactoryABI, c.factoryAddress, c.adapterAddress, params)
+}
+
+// predictEscrowAddress computes the CREATE2 address of the escrow that CreateSwap(params) would
+// deposit into against factoryAddress/adapterAddress, fetching the factory's implementation
+// address through cache (shared between XMREscrowClient and contractorV0 so both paths fetch it
+// at most once).
+func predictEscrowAddress(
+	ctx context.Context,
+	cache *implementationCache,
+	client *ethclient.Client,
+	escrowFactoryABI abi.ABI,
+	factoryAddress common.Address,
+	adapterAddress common.Address,
+	params SwapParams,
+) (common.Address, error) {
+	implementation, err := cache.get(ctx, client, escrowFactoryABI, factoryAddress)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	salt, err := escrowSalt(adapterAddress, params)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	initCodeHash := crypto.Keccak256Hash(minimalProxyInitCode(implementation))
+	return create2Address(factoryAddress, salt, initCodeHash), nil
+}
+
+// implementationCache fetches and caches a factory's escrow implementation address, which is
+// immutable once deployed. Only a successful fetch is cached: a transient RPC failure on the
+// first call is retried on the next call instead of poisoning every later caller for the process
+// lifetime.
+type implementationCache struct {
+	mu    sync.Mutex
+	value common.Address
+}
+
+func (c *implementationCache) get(
+	ctx context.Context,
+	client *ethclient.Client,
+	escrowFactoryABI abi.ABI,
+	factoryAddress common.Address,
+) (common.Address, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.value != (common.Address{}) {
+		return c.value, nil
+	}
+
+	data, err := escrowFactoryABI.Pack("implementation")
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	callMsg := ethereum.CallMsg{To: &factoryAddress, Data: data}
+	result, err := client.CallContract(ctx, callMsg, nil)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	var implementation common.Address
+	if err := escrowFactoryABI.UnpackIntoInterface(&implementation, "implementation", result); err != nil {
+		return common.Address{}, err
+	}
+
+	c.value = implementation
+	return c.value, nil
+}
+
+// escrowSalt computes the CREATE2 salt for a new escrow deposited through adapter with params.
+func escrowSalt(adapter common.Address, params SwapParams) (common.Hash, error) {
+	encoded, err := escrowSaltArgs.Pack(
+		adapter,
+		params.Claimer,
+		params.ClaimCommitment,
+		params.RefundCommitment,
+		params.Timeout1,
+		params.Timeout2,
+		params.Asset,
+		params.Value,
+	)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+// minimalProxyInitCode returns the EIP-1167 minimal proxy init code that clones implementation.
+func minimalProxyInitCode(implementation common.Address) []byte {
+	code := make([]byte, 0, len(minimalProxyPrefix)+common.AddressLength+len(minimalProxySuffix))
+	code = append(code, minimalProxyPrefix...)
+	code = append(code, implementation.Bytes()...)
+	code = append(code, minimalProxySuffix...)
+	return code
+}
+
+// create2Address derives the CREATE2 address deployed by factory for salt and initCodeHash:
+// keccak256(0xff || factory || salt || initCodeHash)[12:].
+func create2Address(factory common.Address, salt, initCodeHash common.Hash) common.Address {
+	data := make([]byte, 0, 1+common.AddressLength+common.HashLength+common.HashLength)
+	data = append(data, create2Prefix)
+	data = append(data, factory.Bytes()...)
+	data = append(data, salt.Bytes()...)
+	data = append(data, initCodeHash.Bytes()...)
+	return common.BytesToAddress(crypto.Keccak256(data)[12:])
+}
+
+// mustABIType builds an abi.Type from its Solidity type string, panicking on failure. It is only
+// ever called with constant, known-valid type strings at package init.
+func mustABIType(solType string) abi.Type {
+	typ, err := abi.NewType(solType, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return typ
+}
@@ -0,0 +1,296 @@
+package oneinch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// TestPredictEscrowAddressMatchesFactory guards against the local CREATE2 computation in
+// address.go silently diverging from what the factory's calculateEscrowAddress would return. A
+// divergence here would let a counterparty's claimed escrow address pass PredictEscrowAddress
+// even though it isn't the address the factory actually deposits into.
+//
+// It spins up a fake JSON-RPC server standing in for the factory contract. For
+// calculateEscrowAddress, the server decodes the ABI-encoded call params and derives the expected
+// address via referenceCreate2EscrowAddress, which reuses address.go's own salt and minimal-proxy
+// init code bytes (this repo has no second, independent source for the exact bytecode the real
+// factory clones) but computes the final CREATE2 address with go-ethereum's crypto.CreateAddress2
+// rather than address.go's own hand-rolled create2Address. That makes this a pinning/regression
+// test against accidental drift in the CREATE2 formula or salt/init-code plumbing, such as a
+// reordered salt argument or a byte dropped while threading params through — it is not a
+// correctness oracle for the minimal-proxy prefix/suffix literals themselves, since a wrong value
+// for those would be wrong identically in address.go and here.
+func TestPredictEscrowAddressMatchesFactory(t *testing.T) {
+	escrowFactoryABI, err := loadEscrowFactoryABI()
+	if err != nil {
+		t.Fatalf("loadEscrowFactoryABI() error = %v", err)
+	}
+
+	factoryAddress := common.HexToAddress("0x1234")
+	adapterAddress := common.HexToAddress("0x5678")
+	implementation := common.HexToAddress("0x9abc")
+
+	params := SwapParams{
+		Claimer:          common.HexToAddress("0xc1a1"),
+		ClaimCommitment:  [32]byte{1, 2, 3},
+		RefundCommitment: [32]byte{4, 5, 6},
+		Timeout1:         big.NewInt(1_000),
+		Timeout2:         big.NewInt(2_000),
+		Asset:            common.HexToAddress("0xa55e7"),
+		Value:            big.NewInt(500_000_000_000_000_000),
+	}
+
+	server := httptest.NewServer(factoryCallHandler(t, escrowFactoryABI, factoryAddress, implementation))
+	defer server.Close()
+
+	client, err := ethclient.Dial(server.URL)
+	if err != nil {
+		t.Fatalf("ethclient.Dial() error = %v", err)
+	}
+	defer client.Close()
+
+	predicted, err := predictEscrowAddress(
+		context.Background(), &implementationCache{}, client, escrowFactoryABI, factoryAddress, adapterAddress, params,
+	)
+	if err != nil {
+		t.Fatalf("predictEscrowAddress() error = %v", err)
+	}
+
+	onChain, err := callCalculateEscrowAddress(context.Background(), client, escrowFactoryABI, factoryAddress, adapterAddress, params)
+	if err != nil {
+		t.Fatalf("callCalculateEscrowAddress() error = %v", err)
+	}
+
+	if predicted != onChain {
+		t.Fatalf("predictEscrowAddress() = %s, want %s (factory's calculateEscrowAddress)", predicted, onChain)
+	}
+}
+
+// callCalculateEscrowAddress mirrors contractorV0.EscrowAddress's RPC fallback path, packing and
+// calling calculateEscrowAddress directly against client.
+func callCalculateEscrowAddress(
+	ctx context.Context,
+	client *ethclient.Client,
+	escrowFactoryABI abi.ABI,
+	factoryAddress, adapterAddress common.Address,
+	params SwapParams,
+) (common.Address, error) {
+	data, err := escrowFactoryABI.Pack("calculateEscrowAddress",
+		adapterAddress,
+		params.Claimer,
+		params.ClaimCommitment,
+		params.RefundCommitment,
+		params.Timeout1,
+		params.Timeout2,
+		params.Asset,
+		params.Value)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	result, err := client.CallContract(ctx, ethereum.CallMsg{To: &factoryAddress, Data: data}, nil)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	var escrowAddress common.Address
+	if err := escrowFactoryABI.UnpackIntoInterface(&escrowAddress, "calculateEscrowAddress", result); err != nil {
+		return common.Address{}, err
+	}
+	return escrowAddress, nil
+}
+
+// factoryCallHandler serves eth_call requests for a fake XMREscrowFactory: "implementation"
+// returns implementation, and "calculateEscrowAddress" returns referenceCreate2EscrowAddress's
+// reference CREATE2 address for the decoded params.
+func factoryCallHandler(t *testing.T, escrowFactoryABI abi.ABI, factoryAddress, implementation common.Address) http.HandlerFunc {
+	implementationMethod := escrowFactoryABI.Methods["implementation"]
+	calculateMethod := escrowFactoryABI.Methods["calculateEscrowAddress"]
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeRPCError(t, w, nil, fmt.Errorf("failed to decode JSON-RPC request: %w", err))
+			return
+		}
+		if req.Method != "eth_call" {
+			writeRPCError(t, w, req.ID, fmt.Errorf("unexpected JSON-RPC method %q", req.Method))
+			return
+		}
+
+		var callArg struct {
+			Data hexutil.Bytes `json:"data"`
+		}
+		if err := json.Unmarshal(req.Params[0], &callArg); err != nil {
+			writeRPCError(t, w, req.ID, fmt.Errorf("failed to decode eth_call params: %w", err))
+			return
+		}
+		data := []byte(callArg.Data)
+		if len(data) < 4 {
+			writeRPCError(t, w, req.ID, fmt.Errorf("eth_call data too short to contain a selector: %x", data))
+			return
+		}
+
+		var result []byte
+		var err error
+		switch {
+		case bytes.Equal(data[:4], implementationMethod.ID):
+			result, err = implementationMethod.Outputs.Pack(implementation)
+		case bytes.Equal(data[:4], calculateMethod.ID):
+			var args []interface{}
+			args, err = calculateMethod.Inputs.Unpack(data[4:])
+			if err == nil {
+				refAddr := referenceCreate2EscrowAddress(
+					factoryAddress, implementation,
+					args[0].(common.Address), args[1].(common.Address),
+					args[2].([32]byte), args[3].([32]byte),
+					args[4].(*big.Int), args[5].(*big.Int),
+					args[6].(common.Address), args[7].(*big.Int),
+				)
+				result, err = calculateMethod.Outputs.Pack(refAddr)
+			}
+		default:
+			err = fmt.Errorf("unexpected eth_call selector %x", data[:4])
+		}
+		if err != nil {
+			writeRPCError(t, w, req.ID, err)
+			return
+		}
+
+		writeRPCResult(t, w, req.ID, result)
+	}
+}
+
+// writeRPCResult writes a successful JSON-RPC response carrying result as a hex-encoded string.
+func writeRPCResult(t *testing.T, w http.ResponseWriter, id json.RawMessage, result []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": id, "result": hexutil.Encode(result)}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		t.Errorf("failed to encode JSON-RPC response: %v", err)
+	}
+}
+
+// writeRPCError reports handlerErr as a test failure (it indicates a bug in the fake server
+// itself, e.g. a malformed request) and writes it back as a JSON-RPC error response. t.Errorf,
+// unlike t.Fatal, is safe to call from the httptest server's own goroutine.
+func writeRPCError(t *testing.T, w http.ResponseWriter, id json.RawMessage, handlerErr error) {
+	t.Errorf("fake factory RPC handler error: %v", handlerErr)
+	writeRPCErrorResponse(w, id, handlerErr)
+}
+
+// writeRPCErrorResponse writes rpcErr back as a JSON-RPC error response without failing the test,
+// for use when the error is a deliberately simulated RPC failure rather than a harness bug.
+func writeRPCErrorResponse(w http.ResponseWriter, id json.RawMessage, rpcErr error) {
+	w.Header().Set("Content-Type", "application/json")
+	resp := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error":   map[string]interface{}{"code": -32000, "message": rpcErr.Error()},
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// referenceCreate2EscrowAddress re-derives the CREATE2 address a deposit(...) call with these
+// params would be created at. It reuses address.go's own escrowSalt and minimalProxyInitCode
+// (there is no second source for the exact minimal-proxy bytecode the real factory clones in this
+// repo, so duplicating those literals here would only risk a second copy silently drifting from
+// the first), but computes the final address with go-ethereum's crypto.CreateAddress2 instead of
+// address.go's own create2Address, so the two CREATE2 formula implementations are independent of
+// each other. See TestPredictEscrowAddressMatchesFactory's doc comment for what this can and
+// can't catch.
+func referenceCreate2EscrowAddress(
+	factory, implementation, adapter, claimer common.Address,
+	claimCommitment, refundCommitment [32]byte,
+	timeout1, timeout2 *big.Int,
+	asset common.Address,
+	value *big.Int,
+) common.Address {
+	salt, err := escrowSalt(adapter, SwapParams{
+		Claimer:          claimer,
+		ClaimCommitment:  claimCommitment,
+		RefundCommitment: refundCommitment,
+		Timeout1:         timeout1,
+		Timeout2:         timeout2,
+		Asset:            asset,
+		Value:            value,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	initCodeHash := crypto.Keccak256Hash(minimalProxyInitCode(implementation))
+	return crypto.CreateAddress2(factory, salt, initCodeHash.Bytes())
+}
+
+func TestImplementationCacheDoesNotCacheErrors(t *testing.T) {
+	escrowFactoryABI, err := loadEscrowFactoryABI()
+	if err != nil {
+		t.Fatalf("loadEscrowFactoryABI() error = %v", err)
+	}
+
+	var fail bool
+	implementation := common.HexToAddress("0x9abc")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeRPCError(t, w, nil, fmt.Errorf("failed to decode JSON-RPC request: %w", err))
+			return
+		}
+
+		if fail {
+			writeRPCErrorResponse(w, req.ID, errors.New("transiently unavailable"))
+			return
+		}
+
+		result, err := escrowFactoryABI.Methods["implementation"].Outputs.Pack(implementation)
+		if err != nil {
+			writeRPCError(t, w, req.ID, fmt.Errorf("failed to pack implementation() result: %w", err))
+			return
+		}
+		writeRPCResult(t, w, req.ID, result)
+	}))
+	defer server.Close()
+
+	client, err := ethclient.Dial(server.URL)
+	if err != nil {
+		t.Fatalf("ethclient.Dial() error = %v", err)
+	}
+	defer client.Close()
+
+	cache := &implementationCache{}
+	factoryAddress := common.HexToAddress("0x1234")
+
+	fail = true
+	if _, err := cache.get(context.Background(), client, escrowFactoryABI, factoryAddress); err == nil {
+		t.Fatal("cache.get() expected an error on the first (failing) RPC call, got nil")
+	}
+
+	fail = false
+	got, err := cache.get(context.Background(), client, escrowFactoryABI, factoryAddress)
+	if err != nil {
+		t.Fatalf("cache.get() error after the node recovered = %v", err)
+	}
+	if got != implementation {
+		t.Fatalf("cache.get() = %s, want %s", got, implementation)
+	}
+}
@@ -0,0 +1,128 @@
+package oneinch
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// PermitSig is a pre-signed EIP-2612 permit authorizing Spender to pull up to Value of a token
+// from Owner without a separate on-chain approve transaction. Callers that already hold a permit
+// signature (e.g. collected from a counterparty or a wallet) can supply it via
+// SwapParams.PermitData so CreateSwap funds the escrow in a single transaction.
+type PermitSig struct {
+	Owner    common.Address
+	Spender  common.Address
+	Value    *big.Int
+	Deadline *big.Int
+	V        uint8
+	R        [32]byte
+	S        [32]byte
+}
+
+// EnsureAllowance checks token's current allowance from owner to spender and, if it's below
+// value, sends an approve transaction for value and waits for it to be mined. It returns nil
+// without sending anything if the existing allowance already covers value.
+//
+// Some widely-used ERC-20s (e.g. USDT) revert approve when the current allowance is non-zero and
+// the new value differs, to prevent a known front-running issue with the standard approve flow.
+// If current is non-zero, EnsureAllowance first approves 0 before approving value, which is safe
+// against every ERC-20 (compliant or not) and costs an extra transaction only for the tokens that
+// actually need it.
+func EnsureAllowance(
+	ctx context.Context,
+	client *ethclient.Client,
+	txManager *TxManager,
+	token, owner, spender common.Address,
+	value *big.Int,
+) error {
+	erc20ABI, err := loadERC20ABI()
+	if err != nil {
+		return err
+	}
+
+	current, err := allowanceOf(ctx, client, erc20ABI, token, owner, spender)
+	if err != nil {
+		return fmt.Errorf("failed to read token allowance: %w", err)
+	}
+	if current.Cmp(value) >= 0 {
+		return nil
+	}
+
+	contract := bind.NewBoundContract(token, erc20ABI, client, client, client)
+	approve := func(amount *big.Int) error {
+		_, _, err := txManager.SendAndWait(ctx, func(auth *bind.TransactOpts) (*types.Transaction, error) {
+			return contract.Transact(auth, "approve", spender, amount)
+		})
+		return err
+	}
+
+	if current.Sign() > 0 {
+		if err := approve(big.NewInt(0)); err != nil {
+			return fmt.Errorf("failed to reset token allowance to zero: %w", err)
+		}
+	}
+
+	if err := approve(value); err != nil {
+		return fmt.Errorf("failed to approve token allowance: %w", err)
+	}
+	return nil
+}
+
+func allowanceOf(
+	ctx context.Context,
+	client *ethclient.Client,
+	erc20ABI abi.ABI,
+	token, owner, spender common.Address,
+) (*big.Int, error) {
+	data, err := erc20ABI.Pack("allowance", owner, spender)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := client.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var allowance *big.Int
+	if err := erc20ABI.UnpackIntoInterface(&allowance, "allowance", result); err != nil {
+		return nil, err
+	}
+	return allowance, nil
+}
+
+// loadERC20ABI loads the minimal standard ERC-20 surface EnsureAllowance needs.
+func loadERC20ABI() (abi.ABI, error) {
+	const erc20ABIJSON = `[
+{
+"inputs": [
+{"internalType": "address", "name": "owner", "type": "address"},
+{"internalType": "address", "name": "spender", "type": "address"}
+],
+"name": "allowance",
+"outputs": [{"internalType": "uint256", "name": "", "type": "uint256"}],
+"stateMutability": "view",
+"type": "function"
+},
+{
+"inputs": [
+{"internalType": "address", "name": "spender", "type": "address"},
+{"internalType": "uint256", "name": "value", "type": "uint256"}
+],
+"name": "approve",
+"outputs": [{"internalType": "bool", "name": "", "type": "bool"}],
+"stateMutability": "nonpayable",
+"type": "function"
+}
+]`
+	return abi.JSON(strings.NewReader(erc20ABIJSON))
+}
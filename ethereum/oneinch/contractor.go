@@ -0,0 +1,355 @@
+package oneinch
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ContractorVersion identifies a 1inch escrow ABI generation. It is read from the on-chain
+// version byte (see ContractorFactory.onChainVersion) so that upgrading the escrow ABI doesn't
+// require changing any call site in this package.
+type ContractorVersion uint8
+
+const (
+	// ContractorV0 is the original escrow ABI: a single factory `deposit` call and per-escrow
+	// `withdraw`/`refund`/`getState` calls, with no on-chain version byte.
+	ContractorV0 ContractorVersion = 0
+	// ContractorV1 targets the next escrow ABI generation. It is implemented identically to V0
+	// today; swap in the upgraded ABI/encoding here once the V1 escrow contract is deployed.
+	ContractorV1 ContractorVersion = 1
+)
+
+// Contractor is the version-specific boundary between this package and the 1inch escrow ABI.
+// dex-space code should depend on this interface rather than on abigen types directly, so that
+// an escrow ABI upgrade only requires a new Contractor implementation, not call-site changes.
+type Contractor interface {
+	// CreateSwap deposits funds into a new escrow for params, returning the deposit transaction,
+	// its receipt, and the address of the escrow that was created.
+	CreateSwap(ctx context.Context, params SwapParams) (*types.Transaction, *types.Receipt, common.Address, error)
+	// Claim withdraws from escrowAddress by revealing secret.
+	Claim(ctx context.Context, escrowAddress common.Address, secret [32]byte) (*types.Transaction, *types.Receipt, error)
+	// Refund reclaims funds from escrowAddress after its timeout has passed.
+	Refund(ctx context.Context, escrowAddress common.Address, refundKey [32]byte) (*types.Transaction, *types.Receipt, error)
+	// State returns the current on-chain state of the escrow at escrowAddress.
+	State(ctx context.Context, escrowAddress common.Address) (uint8, error)
+	// EscrowAddress predicts the escrow address that CreateSwap(params) would deposit into.
+	EscrowAddress(ctx context.Context, params SwapParams) (common.Address, error)
+}
+
+// contractorDeps are the shared resources a Contractor implementation needs, independent of
+// which escrow ABI version it speaks.
+type contractorDeps struct {
+	client           *ethclient.Client
+	txManager        *TxManager
+	factoryAddress   common.Address
+	adapterAddress   common.Address
+	escrowSrcABI     abi.ABI
+	escrowFactoryABI abi.ABI
+	swapAdapterABI   abi.ABI
+
+	// implCache lets contractorV0.EscrowAddress predict an escrow's CREATE2 address locally
+	// instead of calling the factory's calculateEscrowAddress over RPC. It is shared with
+	// XMREscrowClient.PredictEscrowAddress so both paths fetch the implementation address at
+	// most once.
+	implCache *implementationCache
+}
+
+// contractorConstructor builds the Contractor for one escrow ABI version from shared deps.
+type contractorConstructor func(deps contractorDeps) Contractor
+
+// ContractorFactory resolves the Contractor implementation to use for a given escrow, so that
+// callers never need to know which ABI version a particular escrow address was deployed with.
+type ContractorFactory struct {
+	deps contractorDeps
+
+	constructors map[ContractorVersion]contractorConstructor
+
+	// versionOverridesMu guards versionOverrides, since SetVersionOverride can be called for a
+	// staged rollout while ForCreate/ForEscrow are resolving contractors for swaps already in
+	// flight.
+	versionOverridesMu sync.RWMutex
+	// versionOverrides pins a chain ID to a specific contractor version, bypassing the on-chain
+	// version byte lookup. Useful for a staged rollout before every escrow exposes it.
+	versionOverrides map[uint64]ContractorVersion
+}
+
+// NewContractorFactory creates a ContractorFactory with the built-in V0 and V1 constructors
+// registered.
+func NewContractorFactory(deps contractorDeps) *ContractorFactory {
+	return &ContractorFactory{
+		deps: deps,
+		constructors: map[ContractorVersion]contractorConstructor{
+			ContractorV0: newContractorV0,
+			ContractorV1: newContractorV1,
+		},
+		versionOverrides: make(map[uint64]ContractorVersion),
+	}
+}
+
+// SetVersionOverride pins chainID to version, bypassing the on-chain version byte lookup for
+// escrows on that chain.
+func (f *ContractorFactory) SetVersionOverride(chainID *big.Int, version ContractorVersion) {
+	f.versionOverridesMu.Lock()
+	defer f.versionOverridesMu.Unlock()
+	f.versionOverrides[chainID.Uint64()] = version
+}
+
+// versionOverride returns the version pinned for chainID, if any.
+func (f *ContractorFactory) versionOverride(chainID *big.Int) (ContractorVersion, bool) {
+	f.versionOverridesMu.RLock()
+	defer f.versionOverridesMu.RUnlock()
+	version, ok := f.versionOverrides[chainID.Uint64()]
+	return version, ok
+}
+
+// ForCreate returns the Contractor to use when creating a new swap on chainID. New escrows are
+// always deposited against the newest registered version unless a version override says
+// otherwise.
+func (f *ContractorFactory) ForCreate(chainID *big.Int) (Contractor, error) {
+	version := f.latestVersion()
+	if v, ok := f.versionOverride(chainID); ok {
+		version = v
+	}
+	return f.forVersion(version)
+}
+
+// ForEscrow returns the Contractor for an already-deployed escrow, resolving its ABI version
+// from the version override map and falling back to the escrow's on-chain version byte.
+func (f *ContractorFactory) ForEscrow(
+	ctx context.Context,
+	chainID *big.Int,
+	escrowAddress common.Address,
+) (Contractor, error) {
+	if version, ok := f.versionOverride(chainID); ok {
+		return f.forVersion(version)
+	}
+
+	version, err := f.onChainVersion(ctx, escrowAddress)
+	if err != nil {
+		return nil, err
+	}
+	return f.forVersion(version)
+}
+
+func (f *ContractorFactory) forVersion(version ContractorVersion) (Contractor, error) {
+	ctor, ok := f.constructors[version]
+	if !ok {
+		return nil, fmt.Errorf("oneinch: no contractor registered for escrow version %d", version)
+	}
+	return ctor(f.deps), nil
+}
+
+func (f *ContractorFactory) latestVersion() ContractorVersion {
+	latest := ContractorV0
+	for version := range f.constructors {
+		if version > latest {
+			latest = version
+		}
+	}
+	return latest
+}
+
+// onChainVersion reads the single-byte version tag from the escrow contract. Escrows deployed
+// before versioning was introduced don't implement this call, so any failure is treated as V0
+// rather than surfaced as an error.
+func (f *ContractorFactory) onChainVersion(ctx context.Context, escrowAddress common.Address) (ContractorVersion, error) {
+	data, err := f.deps.escrowSrcABI.Pack("version")
+	if err != nil {
+		return ContractorV0, nil
+	}
+
+	result, err := f.deps.client.CallContract(ctx, ethereum.CallMsg{To: &escrowAddress, Data: data}, nil)
+	if err != nil {
+		return ContractorV0, nil
+	}
+
+	var version uint8
+	if err := f.deps.escrowSrcABI.UnpackIntoInterface(&version, "version", result); err != nil {
+		return ContractorV0, nil
+	}
+	return ContractorVersion(version), nil
+}
+
+// contractorV0 implements Contractor against the original escrow ABI.
+type contractorV0 struct {
+	deps contractorDeps
+}
+
+func newContractorV0(deps contractorDeps) Contractor {
+	return &contractorV0{deps: deps}
+}
+
+// EscrowAddress predicts the escrow address CreateSwap(params) would deposit into. It first tries
+// the local CREATE2 computation in address.go, which needs no RPC round-trip once the factory's
+// implementation address is cached, and only falls back to the factory's on-chain
+// calculateEscrowAddress if that fails (e.g. the implementation address hasn't been fetched yet
+// and the RPC call for it errors).
+func (c *contractorV0) EscrowAddress(ctx context.Context, params SwapParams) (common.Address, error) {
+	predicted, predictErr := predictEscrowAddress(
+		ctx, c.deps.implCache, c.deps.client, c.deps.escrowFactoryABI,
+		c.deps.factoryAddress, c.deps.adapterAddress, params,
+	)
+	if predictErr == nil {
+		return predicted, nil
+	}
+
+	data, err := c.deps.escrowFactoryABI.Pack("calculateEscrowAddress",
+		c.deps.adapterAddress,
+		params.Claimer,
+		params.ClaimCommitment,
+		params.RefundCommitment,
+		params.Timeout1,
+		params.Timeout2,
+		params.Asset,
+		params.Value)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	callMsg := ethereum.CallMsg{
+		To:   &c.deps.factoryAddress,
+		Data: data,
+	}
+	result, err := c.deps.client.CallContract(ctx, callMsg, nil)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	var escrowAddress common.Address
+	if err := c.deps.escrowFactoryABI.UnpackIntoInterface(&escrowAddress, "calculateEscrowAddress", result); err != nil {
+		return common.Address{}, err
+	}
+	return escrowAddress, nil
+}
+
+func (c *contractorV0) CreateSwap(
+	ctx context.Context,
+	params SwapParams,
+) (*types.Transaction, *types.Receipt, common.Address, error) {
+	escrowAddress, err := c.EscrowAddress(ctx, params)
+	if err != nil {
+		return nil, nil, common.Address{}, err
+	}
+
+	isToken := params.Asset != (common.Address{})
+
+	if isToken && params.PermitData != nil {
+		tx, receipt, err := c.createSwapWithPermit(ctx, params)
+		if err != nil {
+			return nil, nil, common.Address{}, err
+		}
+		return tx, receipt, escrowAddress, nil
+	}
+
+	if isToken {
+		owner := c.deps.txManager.Address()
+		if err := EnsureAllowance(ctx, c.deps.client, c.deps.txManager, params.Asset, owner, c.deps.factoryAddress, params.Value); err != nil {
+			return nil, nil, common.Address{}, fmt.Errorf("failed to ensure token allowance: %w", err)
+		}
+	}
+
+	contract := bind.NewBoundContract(c.deps.factoryAddress, c.deps.escrowFactoryABI, c.deps.client, c.deps.client, c.deps.client)
+	tx, receipt, err := c.deps.txManager.SendAndWait(ctx, func(auth *bind.TransactOpts) (*types.Transaction, error) {
+		if !isToken {
+			auth.Value = params.Value
+		}
+		return contract.Transact(auth, "deposit",
+			c.deps.adapterAddress,
+			params.Claimer,
+			params.ClaimCommitment,
+			params.RefundCommitment,
+			params.Timeout1,
+			params.Timeout2,
+			params.Asset,
+			params.Value)
+	})
+	if err != nil {
+		return nil, nil, common.Address{}, err
+	}
+	return tx, receipt, escrowAddress, nil
+}
+
+// createSwapWithPermit funds a new escrow in a single transaction by routing through the swap
+// adapter's createSwapWithPermit, which calls the token's EIP-2612 permit before depositing.
+func (c *contractorV0) createSwapWithPermit(ctx context.Context, params SwapParams) (*types.Transaction, *types.Receipt, error) {
+	permit := params.PermitData
+	contract := bind.NewBoundContract(c.deps.adapterAddress, c.deps.swapAdapterABI, c.deps.client, c.deps.client, c.deps.client)
+	return c.deps.txManager.SendAndWait(ctx, func(auth *bind.TransactOpts) (*types.Transaction, error) {
+		return contract.Transact(auth, "createSwapWithPermit",
+			params.Claimer,
+			params.ClaimCommitment,
+			params.RefundCommitment,
+			params.Timeout1,
+			params.Timeout2,
+			params.Asset,
+			params.Value,
+			permit.Deadline,
+			permit.V,
+			permit.R,
+			permit.S)
+	})
+}
+
+func (c *contractorV0) Claim(
+	ctx context.Context,
+	escrowAddress common.Address,
+	secret [32]byte,
+) (*types.Transaction, *types.Receipt, error) {
+	contract := bind.NewBoundContract(escrowAddress, c.deps.escrowSrcABI, c.deps.client, c.deps.client, c.deps.client)
+	return c.deps.txManager.SendAndWait(ctx, func(auth *bind.TransactOpts) (*types.Transaction, error) {
+		return contract.Transact(auth, "withdraw", secret)
+	})
+}
+
+func (c *contractorV0) Refund(
+	ctx context.Context,
+	escrowAddress common.Address,
+	refundKey [32]byte,
+) (*types.Transaction, *types.Receipt, error) {
+	contract := bind.NewBoundContract(escrowAddress, c.deps.escrowSrcABI, c.deps.client, c.deps.client, c.deps.client)
+	return c.deps.txManager.SendAndWait(ctx, func(auth *bind.TransactOpts) (*types.Transaction, error) {
+		return contract.Transact(auth, "refund", refundKey)
+	})
+}
+
+func (c *contractorV0) State(ctx context.Context, escrowAddress common.Address) (uint8, error) {
+	data, err := c.deps.escrowSrcABI.Pack("getState")
+	if err != nil {
+		return 0, err
+	}
+
+	callMsg := ethereum.CallMsg{
+		To:   &escrowAddress,
+		Data: data,
+	}
+	result, err := c.deps.client.CallContract(ctx, callMsg, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var state uint8
+	if err := c.deps.escrowSrcABI.UnpackIntoInterface(&state, "getState", result); err != nil {
+		return 0, err
+	}
+	return state, nil
+}
+
+// contractorV1 targets the next escrow ABI generation. It currently delegates to contractorV0
+// byte-for-byte; once the V1 escrow contract ships, its ABI and call encoding should replace the
+// embedded V0 behavior here rather than touching any Contractor call site.
+type contractorV1 struct {
+	contractorV0
+}
+
+func newContractorV1(deps contractorDeps) Contractor {
+	return &contractorV1{contractorV0{deps: deps}}
+}
@@ -0,0 +1,131 @@
+package oneinch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// TestForEscrowResolvesOnChainVersion guards against onChainVersion's version() call silently
+// failing to match the escrow ABI: if the ABI didn't define a version() method, Pack would error
+// on every call and ForEscrow would always fall back to ContractorV0, regardless of what the
+// escrow actually reports. It stands up a fake escrow reporting on-chain version 1 and checks that
+// ForEscrow resolves a *contractorV1 for it.
+func TestForEscrowResolvesOnChainVersion(t *testing.T) {
+	escrowSrcABI, err := loadEscrowSrcABI()
+	if err != nil {
+		t.Fatalf("loadEscrowSrcABI() error = %v", err)
+	}
+
+	versionMethod := escrowSrcABI.Methods["version"]
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeRPCError(t, w, nil, fmt.Errorf("failed to decode JSON-RPC request: %w", err))
+			return
+		}
+
+		result, err := versionMethod.Outputs.Pack(uint8(1))
+		if err != nil {
+			writeRPCError(t, w, req.ID, fmt.Errorf("failed to pack version() result: %w", err))
+			return
+		}
+		writeRPCResult(t, w, req.ID, result)
+	}))
+	defer server.Close()
+
+	client, err := ethclient.Dial(server.URL)
+	if err != nil {
+		t.Fatalf("ethclient.Dial() error = %v", err)
+	}
+	defer client.Close()
+
+	factory := NewContractorFactory(contractorDeps{
+		client:       client,
+		escrowSrcABI: escrowSrcABI,
+	})
+
+	contractor, err := factory.ForEscrow(context.Background(), big.NewInt(1), common.HexToAddress("0xdead"))
+	if err != nil {
+		t.Fatalf("ForEscrow() error = %v", err)
+	}
+	if _, ok := contractor.(*contractorV1); !ok {
+		t.Fatalf("ForEscrow() resolved %T, want *contractorV1 for on-chain version 1", contractor)
+	}
+}
+
+// TestForEscrowFallsBackToV0OnVersionCallFailure checks that an escrow predating the version()
+// method (the RPC call errors) still resolves to ContractorV0 rather than surfacing an error.
+func TestForEscrowFallsBackToV0OnVersionCallFailure(t *testing.T) {
+	escrowSrcABI, err := loadEscrowSrcABI()
+	if err != nil {
+		t.Fatalf("loadEscrowSrcABI() error = %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeRPCError(t, w, nil, fmt.Errorf("failed to decode JSON-RPC request: %w", err))
+			return
+		}
+		writeRPCErrorResponse(w, req.ID, fmt.Errorf("execution reverted"))
+	}))
+	defer server.Close()
+
+	client, err := ethclient.Dial(server.URL)
+	if err != nil {
+		t.Fatalf("ethclient.Dial() error = %v", err)
+	}
+	defer client.Close()
+
+	factory := NewContractorFactory(contractorDeps{
+		client:       client,
+		escrowSrcABI: escrowSrcABI,
+	})
+
+	contractor, err := factory.ForEscrow(context.Background(), big.NewInt(1), common.HexToAddress("0xdead"))
+	if err != nil {
+		t.Fatalf("ForEscrow() error = %v", err)
+	}
+	if _, ok := contractor.(*contractorV0); !ok {
+		t.Fatalf("ForEscrow() resolved %T, want *contractorV0 when version() isn't implemented", contractor)
+	}
+}
+
+// TestSetVersionOverrideConcurrentIsRaceFree guards against a data race on versionOverrides:
+// SetVersionOverride's stated purpose is pinning a chain to a version during a staged rollout,
+// which means calling it while ForCreate/ForEscrow are resolving contractors for swaps already in
+// flight is expected usage, not a misuse. Run with -race; it fails before versionOverrides was
+// moved under its own mutex.
+func TestSetVersionOverrideConcurrentIsRaceFree(t *testing.T) {
+	factory := NewContractorFactory(contractorDeps{})
+	chainID := big.NewInt(1)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				factory.SetVersionOverride(chainID, ContractorV1)
+				return
+			}
+			if _, err := factory.ForCreate(chainID); err != nil {
+				t.Errorf("ForCreate() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -5,15 +5,13 @@ import (
 "context"
 "crypto/ecdsa"
 "errors"
+"fmt"
 "math/big"
 "strings"
 
-"github.com/ethereum/go-ethereum"
 "github.com/ethereum/go-ethereum/accounts/abi"
-"github.com/ethereum/go-ethereum/accounts/abi/bind"
 "github.com/ethereum/go-ethereum/common"
 "github.com/ethereum/go-ethereum/core/types"
-"github.com/ethereum/go-ethereum/crypto"
 "github.com/ethereum/go-ethereum/ethclient"
 
 contracts "github.com/athanorlabs/atomic-swap/ethereum"
@@ -43,20 +41,39 @@ Timeout1         *big.Int       // First timeout (for claiming)
 Timeout2         *big.Int       // Second timeout (for refunding)
 Asset            common.Address // Token address (zero address for ETH)
 Value            *big.Int       // Amount to swap
+
+// PermitData, if set, is a pre-signed EIP-2612 permit for Value of Asset. CreateSwap uses it to
+// fund an ERC-20 escrow in a single transaction instead of a separate approve beforehand.
+PermitData *PermitSig
 }
 
-// XMREscrowClient provides methods to interact with the 1inch escrow contracts
+// XMREscrowClient is a thin dispatcher over the 1inch escrow contracts. For creating, claiming,
+// refunding and reading the state of a swap it never packs or unpacks ABI calldata itself; it
+// loads the Contractor for the chain/escrow in question and delegates to it, so an escrow ABI
+// upgrade is a new Contractor implementation rather than a change to this type or its callers.
+// WatchSwap and PredictEscrowAddress are not routed through a Contractor: they decode escrow logs
+// and call the factory's implementation() directly.
 type XMREscrowClient struct {
 client         *ethclient.Client
 factoryAddress common.Address
 adapterAddress common.Address
 swapCreator    *contracts.SwapCreator
 privateKey     *ecdsa.PrivateKey
+txManager      *TxManager
+chainID        *big.Int
+contractors    *ContractorFactory
 
-// Contract ABIs
+// escrowSrcABI and escrowFactoryABI are kept here (in addition to inside contractorDeps)
+// because WatchSwap and PredictEscrowAddress work directly off the chain and aren't routed
+// through a versioned Contractor.
 escrowSrcABI     abi.ABI
 escrowFactoryABI abi.ABI
-swapAdapterABI   abi.ABI
+
+// implCache caches the factory's escrow implementation address, which is immutable once
+// deployed. It is shared with contractorDeps so contractorV0.EscrowAddress can predict the same
+// address PredictEscrowAddress would, without a second implementation() RPC round-trip. See
+// PredictEscrowAddress.
+implCache *implementationCache
 }
 
 // NewXMREscrowClient creates a new client for interacting with 1inch escrow contracts
@@ -103,188 +120,85 @@ if err != nil {
 return nil, err
 }
 
-return &XMREscrowClient{
-client:          client,
-swapCreator:     swapCreator,
-factoryAddress:  factoryAddress,
-adapterAddress:  adapterAddress,
-privateKey:      privateKey,
-escrowSrcABI:    escrowSrcABI,
-escrowFactoryABI: escrowFactoryABI,
-swapAdapterABI:  swapAdapterABI,
-}, nil
-}
-
-// getAuth returns a transaction auth object for sending transactions
-func (c *XMREscrowClient) getAuth(ctx context.Context) (*bind.TransactOpts, error) {
-nonce, err := c.client.PendingNonceAt(ctx, crypto.PubkeyToAddress(c.privateKey.PublicKey))
-if err != nil {
-return nil, err
-}
-
-gasPrice, err := c.client.SuggestGasPrice(ctx)
-if err != nil {
-return nil, err
-}
-
-chainID, err := c.client.ChainID(ctx)
-if err != nil {
-return nil, err
-}
-
-auth, err := bind.NewKeyedTransactorWithChainID(c.privateKey, chainID)
-if err != nil {
-return nil, err
-}
-
-auth.Nonce = big.NewInt(int64(nonce))
-auth.Value = big.NewInt(0)     // in wei
-auth.GasLimit = uint64(300000) // in units
-auth.GasPrice = gasPrice
-
-return auth, nil
-}
-
-// calculateEscrowAddress calculates the address of the escrow contract for a given swap
-func (c *XMREscrowClient) calculateEscrowAddress(ctx context.Context, params SwapParams) (common.Address, error) {
-// Pack the function call
-data, err := c.escrowFactoryABI.Pack("calculateEscrowAddress",
-c.adapterAddress,
-params.Claimer,
-params.ClaimCommitment,
-params.RefundCommitment,
-params.Timeout1,
-params.Timeout2,
-params.Asset,
-params.Value)
+chainID, err := client.ChainID(context.Background())
 if err != nil {
-return common.Address{}, err
-}
-
-// Call the contract
-callMsg := ethereum.CallMsg{
-To:   &c.factoryAddress,
-Data: data,
+return nil, fmt.Errorf("failed to fetch chain ID: %w", err)
 }
 
-result, err := c.client.CallContract(ctx, callMsg, nil)
-if err != nil {
-return common.Address{}, err
-}
+txManager := NewTxManager(client, privateKey, chainID, newAutoFeeStrategy())
+implCache := &implementationCache{}
+contractors := NewContractorFactory(contractorDeps{
+client:           client,
+txManager:        txManager,
+factoryAddress:   factoryAddress,
+adapterAddress:   adapterAddress,
+escrowSrcABI:     escrowSrcABI,
+escrowFactoryABI: escrowFactoryABI,
+swapAdapterABI:   swapAdapterABI,
+implCache:        implCache,
+})
 
-// Unpack the result
-var escrowAddress common.Address
-err = c.escrowFactoryABI.UnpackIntoInterface(&escrowAddress, "calculateEscrowAddress", result)
-if err != nil {
-return common.Address{}, err
+return &XMREscrowClient{
+client:         client,
+swapCreator:    swapCreator,
+factoryAddress: factoryAddress,
+adapterAddress: adapterAddress,
+privateKey:     privateKey,
+txManager:      txManager,
+chainID:        chainID,
+contractors:      contractors,
+escrowSrcABI:     escrowSrcABI,
+escrowFactoryABI: escrowFactoryABI,
+implCache:        implCache,
+}, nil
 }
 
-return escrowAddress, nil
+// EnablePrivateSubmission routes all subsequent CreateSwap/ClaimSwap/RefundSwap transactions
+// through cfg's relay instead of the public mempool, falling back to public submission only if
+// cfg.AllowPublicFallback is true and the relay is unreachable. ClaimSwap is the highest-value
+// target for this since it reveals the atomic swap secret, but private routing applies uniformly
+// because all three operations share one TxManager, and therefore one nonce sequence, per account.
+func (c *XMREscrowClient) EnablePrivateSubmission(cfg PrivateTxConfig) error {
+	return c.txManager.WithPrivateSubmission(cfg)
 }
 
-// CreateSwap creates a new swap using the 1inch escrow contracts
-func (c *XMREscrowClient) CreateSwap(ctx context.Context, params SwapParams) (*types.Transaction, common.Address, error) {
-// Get transaction auth
-auth, err := c.getAuth(ctx)
+// CreateSwap creates a new swap using the 1inch escrow contracts. It blocks until the deposit
+// transaction is mined, resubmitting with a bumped fee if it stalls.
+func (c *XMREscrowClient) CreateSwap(ctx context.Context, params SwapParams) (*types.Transaction, *types.Receipt, common.Address, error) {
+contractor, err := c.contractors.ForCreate(c.chainID)
 if err != nil {
-return nil, common.Address{}, err
+return nil, nil, common.Address{}, err
 }
-
-// Set the value for ETH swaps
-if params.Asset == (common.Address{}) {
-auth.Value = params.Value
+return contractor.CreateSwap(ctx, params)
 }
 
-// Calculate the escrow address
-escrowAddress, err := c.calculateEscrowAddress(ctx, params)
+// ClaimSwap claims a swap by providing the secret. It blocks until the withdrawal transaction is
+// mined, resubmitting with a bumped fee if it stalls.
+func (c *XMREscrowClient) ClaimSwap(ctx context.Context, escrowAddress common.Address, secret [32]byte) (*types.Transaction, *types.Receipt, error) {
+contractor, err := c.contractors.ForEscrow(ctx, c.chainID, escrowAddress)
 if err != nil {
-return nil, common.Address{}, err
-}
-
-// Create and send the transaction using bound contract
-contract := bind.NewBoundContract(c.factoryAddress, c.escrowFactoryABI, c.client, c.client, c.client)
-tx, err := contract.Transact(auth, "deposit", 
-c.adapterAddress,
-params.Claimer,
-params.ClaimCommitment,
-params.RefundCommitment,
-params.Timeout1,
-params.Timeout2,
-params.Asset,
-params.Value)
-
-if err != nil {
-return nil, common.Address{}, err
-}
-
-return tx, escrowAddress, nil
+return nil, nil, err
 }
-
-// ClaimSwap claims a swap by providing the secret
-func (c *XMREscrowClient) ClaimSwap(ctx context.Context, escrowAddress common.Address, secret [32]byte) (*types.Transaction, error) {
-// Get transaction auth
-auth, err := c.getAuth(ctx)
-if err != nil {
-return nil, err
+return contractor.Claim(ctx, escrowAddress, secret)
 }
 
-// Create and send the transaction using bound contract
-contract := bind.NewBoundContract(escrowAddress, c.escrowSrcABI, c.client, c.client, c.client)
-tx, err := contract.Transact(auth, "withdraw", secret)
-
+// RefundSwap refunds a swap after the timeout has passed. It blocks until the refund transaction
+// is mined, resubmitting with a bumped fee if it stalls.
+func (c *XMREscrowClient) RefundSwap(ctx context.Context, escrowAddress common.Address, refundKey [32]byte) (*types.Transaction, *types.Receipt, error) {
+contractor, err := c.contractors.ForEscrow(ctx, c.chainID, escrowAddress)
 if err != nil {
-return nil, err
-}
-
-return tx, nil
+return nil, nil, err
 }
-
-// RefundSwap refunds a swap after the timeout has passed
-func (c *XMREscrowClient) RefundSwap(ctx context.Context, escrowAddress common.Address, refundKey [32]byte) (*types.Transaction, error) {
-// Get transaction auth
-auth, err := c.getAuth(ctx)
-if err != nil {
-return nil, err
-}
-
-// Create and send the transaction using bound contract
-contract := bind.NewBoundContract(escrowAddress, c.escrowSrcABI, c.client, c.client, c.client)
-tx, err := contract.Transact(auth, "refund", refundKey)
-
-if err != nil {
-return nil, err
-}
-
-return tx, nil
+return contractor.Refund(ctx, escrowAddress, refundKey)
 }
 
 // GetSwapState gets the current state of a swap
 func (c *XMREscrowClient) GetSwapState(ctx context.Context, escrowAddress common.Address) (uint8, error) {
-// Pack the function call
-data, err := c.escrowSrcABI.Pack("getState")
-if err != nil {
-return 0, err
-}
-
-// Call the contract
-callMsg := ethereum.CallMsg{
-To:   &escrowAddress,
-Data: data,
-}
-
-result, err := c.client.CallContract(ctx, callMsg, nil)
+contractor, err := c.contractors.ForEscrow(ctx, c.chainID, escrowAddress)
 if err != nil {
 return 0, err
 }
-
-// Unpack the result
-var state uint8
-err = c.escrowSrcABI.UnpackIntoInterface(&state, "getState", result)
-if err != nil {
-return 0, err
-}
-
-return state, nil
+return contractor.State(ctx, escrowAddress)
 }
 
 // ConvertSwapCreatorSwapToParams converts a SwapCreator.Swap to SwapParams
@@ -327,6 +241,47 @@ const escrowSrcABIJSON = `[
 "outputs": [{"internalType": "uint8", "name": "", "type": "uint8"}],
 "stateMutability": "view",
 "type": "function"
+},
+{
+"inputs": [],
+"name": "version",
+"outputs": [{"internalType": "uint8", "name": "", "type": "uint8"}],
+"stateMutability": "view",
+"type": "function"
+},
+{
+"anonymous": false,
+"inputs": [
+{"indexed": false, "internalType": "address", "name": "depositor", "type": "address"},
+{"indexed": false, "internalType": "uint256", "name": "value", "type": "uint256"}
+],
+"name": "Deposited",
+"type": "event"
+},
+{
+"anonymous": false,
+"inputs": [
+{"indexed": false, "internalType": "bytes32", "name": "secret", "type": "bytes32"}
+],
+"name": "Withdrawn",
+"type": "event"
+},
+{
+"anonymous": false,
+"inputs": [
+{"indexed": false, "internalType": "bytes32", "name": "refundKey", "type": "bytes32"}
+],
+"name": "Refunded",
+"type": "event"
+},
+{
+"anonymous": false,
+"inputs": [
+{"indexed": false, "internalType": "uint256", "name": "timeout1", "type": "uint256"},
+{"indexed": false, "internalType": "uint256", "name": "timeout2", "type": "uint256"}
+],
+"name": "TimeoutChanged",
+"type": "event"
 }
 ]`
 return abi.JSON(strings.NewReader(escrowSrcABIJSON))
@@ -366,6 +321,13 @@ const escrowFactoryABIJSON = `[
 "outputs": [{"internalType": "address", "name": "", "type": "address"}],
 "stateMutability": "payable",
 "type": "function"
+},
+{
+"inputs": [],
+"name": "implementation",
+"outputs": [{"internalType": "address", "name": "", "type": "address"}],
+"stateMutability": "view",
+"type": "function"
 }
 ]`
 return abi.JSON(strings.NewReader(escrowFactoryABIJSON))
@@ -406,6 +368,25 @@ const swapAdapterABIJSON = `[
 "outputs": [],
 "stateMutability": "nonpayable",
 "type": "function"
+},
+{
+"inputs": [
+{"internalType": "address", "name": "_claimer", "type": "address"},
+{"internalType": "bytes32", "name": "_claimCommitment", "type": "bytes32"},
+{"internalType": "bytes32", "name": "_refundCommitment", "type": "bytes32"},
+{"internalType": "uint256", "name": "_timeout1", "type": "uint256"},
+{"internalType": "uint256", "name": "_timeout2", "type": "uint256"},
+{"internalType": "address", "name": "_asset", "type": "address"},
+{"internalType": "uint256", "name": "_value", "type": "uint256"},
+{"internalType": "uint256", "name": "_deadline", "type": "uint256"},
+{"internalType": "uint8", "name": "_v", "type": "uint8"},
+{"internalType": "bytes32", "name": "_r", "type": "bytes32"},
+{"internalType": "bytes32", "name": "_s", "type": "bytes32"}
+],
+"name": "createSwapWithPermit",
+"outputs": [{"internalType": "address", "name": "", "type": "address"}],
+"stateMutability": "nonpayable",
+"type": "function"
 }
 ]`
 return abi.JSON(strings.NewReader(swapAdapterABIJSON))
@@ -0,0 +1,294 @@
+package oneinch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// watchReconnectBaseDelay is the initial backoff after a subscription drops.
+const watchReconnectBaseDelay = 1 * time.Second
+
+// watchReconnectMaxDelay caps the exponential backoff between reconnect attempts.
+const watchReconnectMaxDelay = 30 * time.Second
+
+// escrowEventNames are the events WatchSwap subscribes to, in the order their topics are
+// requested in.
+var escrowEventNames = []string{"Deposited", "Withdrawn", "Refunded", "TimeoutChanged"}
+
+// SwapEventType identifies which escrow event a SwapEvent was decoded from.
+type SwapEventType string
+
+const (
+	// SwapEventDeposited is emitted when an escrow receives its deposit.
+	SwapEventDeposited SwapEventType = "Deposited"
+	// SwapEventWithdrawn is emitted when an escrow is claimed with the secret.
+	SwapEventWithdrawn SwapEventType = "Withdrawn"
+	// SwapEventRefunded is emitted when an escrow is refunded after timeout.
+	SwapEventRefunded SwapEventType = "Refunded"
+	// SwapEventTimeoutChanged is emitted when an escrow's timeouts are updated.
+	SwapEventTimeoutChanged SwapEventType = "TimeoutChanged"
+)
+
+// SwapEvent is a typed, decoded log emitted by a 1inch escrow contract.
+type SwapEvent struct {
+	Type          SwapEventType
+	EscrowAddress common.Address
+	BlockNumber   uint64
+	TxHash        common.Hash
+
+	// Depositor and Value are set for SwapEventDeposited.
+	Depositor common.Address
+	Value     *big.Int
+
+	// Secret is set for SwapEventWithdrawn.
+	Secret [32]byte
+
+	// RefundKey is set for SwapEventRefunded.
+	RefundKey [32]byte
+
+	// Timeout1 and Timeout2 are set for SwapEventTimeoutChanged.
+	Timeout1 *big.Int
+	Timeout2 *big.Int
+}
+
+// WatchSwap subscribes to Deposited, Withdrawn, Refunded, and TimeoutChanged events emitted by
+// the escrow at escrowAddress, returning a channel of typed SwapEvents in place of polling
+// GetSwapState. If fromBlock is non-nil, historical events from that block onward are replayed
+// before live events are streamed, letting a restarted swapd rebuild state from the chain rather
+// than re-querying getState. The subscription reconnects with exponential backoff if it drops,
+// resuming from the last block it successfully delivered. The returned channel is closed once
+// ctx is done.
+func (c *XMREscrowClient) WatchSwap(ctx context.Context, escrowAddress common.Address, fromBlock *big.Int) (<-chan SwapEvent, error) {
+	topics, err := escrowEventTopics(c.escrowSrcABI)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan SwapEvent)
+	go c.watchSwapLoop(ctx, escrowAddress, fromBlock, topics, out)
+	return out, nil
+}
+
+func (c *XMREscrowClient) watchSwapLoop(
+	ctx context.Context,
+	escrowAddress common.Address,
+	fromBlock *big.Int,
+	topics [][]common.Hash,
+	out chan<- SwapEvent,
+) {
+	defer close(out)
+
+	replayFrom := fromBlock
+	delay := watchReconnectBaseDelay
+
+	for ctx.Err() == nil {
+		onConnected := func() { delay = watchReconnectBaseDelay }
+		lastBlock, err := c.streamSwapEvents(ctx, escrowAddress, replayFrom, topics, out, onConnected)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+
+		replayFrom = lastBlock
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > watchReconnectMaxDelay {
+			delay = watchReconnectMaxDelay
+		}
+	}
+}
+
+// streamSwapEvents replays historical logs from fromBlock (if set), then streams live logs until
+// the subscription errors or ctx is done. It returns the block to resume from on the next
+// reconnect attempt. onConnected, if non-nil, is called once the live stream has caught up to the
+// chain head and is ready to deliver logs without a gap, so the caller can reset its reconnect
+// backoff.
+func (c *XMREscrowClient) streamSwapEvents(
+	ctx context.Context,
+	escrowAddress common.Address,
+	fromBlock *big.Int,
+	topics [][]common.Hash,
+	out chan<- SwapEvent,
+	onConnected func(),
+) (*big.Int, error) {
+	lastBlock := fromBlock
+
+	if fromBlock != nil {
+		query := ethereum.FilterQuery{
+			FromBlock: fromBlock,
+			Addresses: []common.Address{escrowAddress},
+			Topics:    topics,
+		}
+		logs, err := c.client.FilterLogs(ctx, query)
+		if err != nil {
+			return fromBlock, fmt.Errorf("failed to replay historical escrow logs: %w", err)
+		}
+		for _, log := range logs {
+			if !deliverSwapLog(ctx, c.escrowSrcABI, log, out) {
+				return lastBlock, nil
+			}
+			lastBlock = new(big.Int).SetUint64(log.BlockNumber + 1)
+		}
+	}
+
+	liveQuery := ethereum.FilterQuery{
+		Addresses: []common.Address{escrowAddress},
+		Topics:    topics,
+	}
+	if lastBlock != nil {
+		liveQuery.FromBlock = lastBlock
+	}
+
+	logCh := make(chan types.Log)
+	sub, err := c.client.SubscribeFilterLogs(ctx, liveQuery, logCh)
+	if err != nil {
+		return lastBlock, fmt.Errorf("failed to subscribe to escrow logs: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	// SubscribeFilterLogs only delivers logs produced after the subscription attaches on the
+	// node; it does not honor liveQuery.FromBlock for backfill. A log produced between the
+	// historical FilterLogs call above and the subscription attaching would otherwise be dropped,
+	// so re-filter up to the current head before trusting the live stream.
+	if lastBlock != nil {
+		gapLogs, err := c.client.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: lastBlock,
+			Addresses: []common.Address{escrowAddress},
+			Topics:    topics,
+		})
+		if err != nil {
+			return lastBlock, fmt.Errorf("failed to close the gap before the escrow log subscription: %w", err)
+		}
+		for _, log := range gapLogs {
+			if !deliverSwapLog(ctx, c.escrowSrcABI, log, out) {
+				return lastBlock, nil
+			}
+			lastBlock = new(big.Int).SetUint64(log.BlockNumber + 1)
+		}
+	}
+
+	if onConnected != nil {
+		onConnected()
+	}
+
+	// Live logs below skipBefore were already delivered by the filters above.
+	skipBefore := lastBlock
+	for {
+		select {
+		case <-ctx.Done():
+			return lastBlock, nil
+		case err := <-sub.Err():
+			return lastBlock, err
+		case log := <-logCh:
+			if skipBefore != nil && log.BlockNumber < skipBefore.Uint64() {
+				continue
+			}
+			if !deliverSwapLog(ctx, c.escrowSrcABI, log, out) {
+				return lastBlock, nil
+			}
+			lastBlock = new(big.Int).SetUint64(log.BlockNumber + 1)
+		}
+	}
+}
+
+// deliverSwapLog decodes log and sends it on out, returning false if ctx was cancelled first.
+// Logs that don't decode to a known escrow event are silently skipped.
+func deliverSwapLog(ctx context.Context, escrowSrcABI abi.ABI, log types.Log, out chan<- SwapEvent) bool {
+	event, err := decodeSwapEvent(escrowSrcABI, log)
+	if err != nil {
+		return true
+	}
+
+	select {
+	case out <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// escrowEventTopics builds the topic filter for escrowEventNames from escrowSrcABI.
+func escrowEventTopics(escrowSrcABI abi.ABI) ([][]common.Hash, error) {
+	ids := make([]common.Hash, 0, len(escrowEventNames))
+	for _, name := range escrowEventNames {
+		event, ok := escrowSrcABI.Events[name]
+		if !ok {
+			return nil, fmt.Errorf("oneinch: escrow ABI missing %s event", name)
+		}
+		ids = append(ids, event.ID)
+	}
+	return [][]common.Hash{ids}, nil
+}
+
+// decodeSwapEvent decodes log into a typed SwapEvent using escrowSrcABI.
+func decodeSwapEvent(escrowSrcABI abi.ABI, log types.Log) (SwapEvent, error) {
+	if len(log.Topics) == 0 {
+		return SwapEvent{}, errors.New("oneinch: log has no topics")
+	}
+
+	event, err := escrowSrcABI.EventByID(log.Topics[0])
+	if err != nil {
+		return SwapEvent{}, err
+	}
+
+	swapEvent := SwapEvent{
+		EscrowAddress: log.Address,
+		BlockNumber:   log.BlockNumber,
+		TxHash:        log.TxHash,
+	}
+
+	switch event.Name {
+	case string(SwapEventDeposited):
+		var decoded struct {
+			Depositor common.Address
+			Value     *big.Int
+		}
+		if err := escrowSrcABI.UnpackIntoInterface(&decoded, event.Name, log.Data); err != nil {
+			return SwapEvent{}, err
+		}
+		swapEvent.Type = SwapEventDeposited
+		swapEvent.Depositor = decoded.Depositor
+		swapEvent.Value = decoded.Value
+	case string(SwapEventWithdrawn):
+		var decoded struct{ Secret [32]byte }
+		if err := escrowSrcABI.UnpackIntoInterface(&decoded, event.Name, log.Data); err != nil {
+			return SwapEvent{}, err
+		}
+		swapEvent.Type = SwapEventWithdrawn
+		swapEvent.Secret = decoded.Secret
+	case string(SwapEventRefunded):
+		var decoded struct{ RefundKey [32]byte }
+		if err := escrowSrcABI.UnpackIntoInterface(&decoded, event.Name, log.Data); err != nil {
+			return SwapEvent{}, err
+		}
+		swapEvent.Type = SwapEventRefunded
+		swapEvent.RefundKey = decoded.RefundKey
+	case string(SwapEventTimeoutChanged):
+		var decoded struct {
+			Timeout1 *big.Int
+			Timeout2 *big.Int
+		}
+		if err := escrowSrcABI.UnpackIntoInterface(&decoded, event.Name, log.Data); err != nil {
+			return SwapEvent{}, err
+		}
+		swapEvent.Type = SwapEventTimeoutChanged
+		swapEvent.Timeout1 = decoded.Timeout1
+		swapEvent.Timeout2 = decoded.Timeout2
+	default:
+		return SwapEvent{}, fmt.Errorf("oneinch: unrecognized escrow event %s", event.Name)
+	}
+
+	return swapEvent, nil
+}
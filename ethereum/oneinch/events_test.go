@@ -0,0 +1,113 @@
+package oneinch
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestDecodeSwapEvent(t *testing.T) {
+	escrowSrcABI, err := loadEscrowSrcABI()
+	if err != nil {
+		t.Fatalf("loadEscrowSrcABI() error = %v", err)
+	}
+
+	escrowAddress := common.HexToAddress("0xabc")
+	txHash := common.HexToHash("0x01")
+	depositor := common.HexToAddress("0xdead")
+	secret := [32]byte{1, 2, 3}
+	refundKey := [32]byte{4, 5, 6}
+
+	tests := []struct {
+		eventName string
+		pack      func() ([]byte, error)
+		check     func(t *testing.T, got SwapEvent)
+	}{
+		{
+			eventName: "Deposited",
+			pack: func() ([]byte, error) {
+				return escrowSrcABI.Events["Deposited"].Inputs.Pack(depositor, big.NewInt(42))
+			},
+			check: func(t *testing.T, got SwapEvent) {
+				if got.Type != SwapEventDeposited || got.Depositor != depositor || got.Value.Cmp(big.NewInt(42)) != 0 {
+					t.Fatalf("unexpected decoded Deposited event: %+v", got)
+				}
+			},
+		},
+		{
+			eventName: "Withdrawn",
+			pack: func() ([]byte, error) {
+				return escrowSrcABI.Events["Withdrawn"].Inputs.Pack(secret)
+			},
+			check: func(t *testing.T, got SwapEvent) {
+				if got.Type != SwapEventWithdrawn || got.Secret != secret {
+					t.Fatalf("unexpected decoded Withdrawn event: %+v", got)
+				}
+			},
+		},
+		{
+			eventName: "Refunded",
+			pack: func() ([]byte, error) {
+				return escrowSrcABI.Events["Refunded"].Inputs.Pack(refundKey)
+			},
+			check: func(t *testing.T, got SwapEvent) {
+				if got.Type != SwapEventRefunded || got.RefundKey != refundKey {
+					t.Fatalf("unexpected decoded Refunded event: %+v", got)
+				}
+			},
+		},
+		{
+			eventName: "TimeoutChanged",
+			pack: func() ([]byte, error) {
+				return escrowSrcABI.Events["TimeoutChanged"].Inputs.Pack(big.NewInt(100), big.NewInt(200))
+			},
+			check: func(t *testing.T, got SwapEvent) {
+				if got.Type != SwapEventTimeoutChanged ||
+					got.Timeout1.Cmp(big.NewInt(100)) != 0 ||
+					got.Timeout2.Cmp(big.NewInt(200)) != 0 {
+					t.Fatalf("unexpected decoded TimeoutChanged event: %+v", got)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.eventName, func(t *testing.T) {
+			data, err := tt.pack()
+			if err != nil {
+				t.Fatalf("failed to pack %s event data: %v", tt.eventName, err)
+			}
+
+			log := types.Log{
+				Address:     escrowAddress,
+				Topics:      []common.Hash{escrowSrcABI.Events[tt.eventName].ID},
+				Data:        data,
+				BlockNumber: 100,
+				TxHash:      txHash,
+			}
+
+			got, err := decodeSwapEvent(escrowSrcABI, log)
+			if err != nil {
+				t.Fatalf("decodeSwapEvent() error = %v", err)
+			}
+			if got.EscrowAddress != escrowAddress || got.BlockNumber != 100 || got.TxHash != txHash {
+				t.Fatalf("decodeSwapEvent() did not preserve log metadata: %+v", got)
+			}
+			tt.check(t, got)
+		})
+	}
+}
+
+func TestDecodeSwapEventUnrecognizedTopic(t *testing.T) {
+	escrowSrcABI, err := loadEscrowSrcABI()
+	if err != nil {
+		t.Fatalf("loadEscrowSrcABI() error = %v", err)
+	}
+
+	log := types.Log{Topics: []common.Hash{common.HexToHash("0xdeadbeef")}}
+	if _, err := decodeSwapEvent(escrowSrcABI, log); err == nil {
+		t.Fatal("decodeSwapEvent() expected an error for an unrecognized topic, got nil")
+	}
+}
@@ -0,0 +1,198 @@
+package oneinch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// defaultTipFloorGwei is the minimum priority fee we will offer, regardless of what the node
+// suggests. Public RPCs sometimes suggest near-zero tips, which can leave a transaction stuck
+// for a long time on congested networks.
+const defaultTipFloorGwei = 1
+
+// defaultFeeCapMultiplier is applied to the latest base fee to build a GasFeeCap with enough
+// headroom to survive a few blocks of base fee increases before a resubmission is needed.
+const defaultFeeCapMultiplier = 2
+
+// resubmitBumpPermille is the minimum increase applied to a stuck transaction's fee fields on
+// resubmission, expressed in thousandths (125 == 12.5%). This comfortably clears the 10% bump
+// most clients require to replace a transaction with the same nonce.
+const resubmitBumpPermille = 125
+
+// gasLimitHeadroomPermille is the safety margin added on top of a raw eth_estimateGas result,
+// expressed in thousandths (200 == 20%). EstimateGas is run against current state, which can
+// shift slightly by the time the transaction actually lands; a bare-minimum gas limit risks an
+// out-of-gas revert on otherwise-valid transactions.
+const gasLimitHeadroomPermille = 200
+
+// probeGasLimit is the placeholder gas limit used when packing a transaction purely to read back
+// its destination, calldata, and value for an explicit EstimateGas call. It must be nonzero so
+// that bind.BoundContract.Transact packs the call without also running its own implicit
+// estimation, and large enough that packing itself never fails for want of gas.
+const probeGasLimit = 30_000_000
+
+// FeeStrategy computes gas pricing for a transaction before it is signed and sent, and knows how
+// to bump that pricing when the same transaction needs to be resubmitted.
+type FeeStrategy interface {
+	// Apply sets the gas price (legacy) or fee cap/tip cap (EIP-1559) fields on auth.
+	Apply(ctx context.Context, client *ethclient.Client, auth *bind.TransactOpts) error
+	// Bump increases the fee fields currently set on auth by at least resubmitBumpPermille.
+	Bump(auth *bind.TransactOpts)
+}
+
+// legacyFeeStrategy prices transactions using the pre-EIP-1559 single gas price field. It is used
+// for chains that don't expose a base fee.
+type legacyFeeStrategy struct{}
+
+// NewLegacyFeeStrategy returns a FeeStrategy that prices transactions with a single gas price,
+// suitable for chains that don't support the EIP-1559 fee market.
+func NewLegacyFeeStrategy() FeeStrategy {
+	return &legacyFeeStrategy{}
+}
+
+func (s *legacyFeeStrategy) Apply(ctx context.Context, client *ethclient.Client, auth *bind.TransactOpts) error {
+	gasPrice, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to suggest gas price: %w", err)
+	}
+	auth.GasPrice = gasPrice
+	auth.GasTipCap = nil
+	auth.GasFeeCap = nil
+	return nil
+}
+
+func (s *legacyFeeStrategy) Bump(auth *bind.TransactOpts) {
+	auth.GasPrice = bumpByPermille(auth.GasPrice, resubmitBumpPermille)
+}
+
+// dynamicFeeStrategy prices type-2 (EIP-1559) transactions from the network's suggested priority
+// fee and latest base fee.
+type dynamicFeeStrategy struct {
+	tipFloor         *big.Int
+	feeCapMultiplier int64
+}
+
+// NewDynamicFeeStrategy returns a FeeStrategy that prices type-2 transactions, flooring the
+// priority fee at tipFloorGwei gwei. A non-positive tipFloorGwei falls back to defaultTipFloorGwei.
+func NewDynamicFeeStrategy(tipFloorGwei int64) FeeStrategy {
+	if tipFloorGwei <= 0 {
+		tipFloorGwei = defaultTipFloorGwei
+	}
+	return &dynamicFeeStrategy{
+		tipFloor:         new(big.Int).Mul(big.NewInt(tipFloorGwei), big.NewInt(params.GWei)),
+		feeCapMultiplier: defaultFeeCapMultiplier,
+	}
+}
+
+func (s *dynamicFeeStrategy) Apply(ctx context.Context, client *ethclient.Client, auth *bind.TransactOpts) error {
+	tip, err := client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to suggest gas tip cap: %w", err)
+	}
+	if tip.Cmp(s.tipFloor) < 0 {
+		tip = new(big.Int).Set(s.tipFloor)
+	}
+
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to fetch latest header: %w", err)
+	}
+	if header.BaseFee == nil {
+		return errors.New("oneinch: chain does not support the EIP-1559 fee market")
+	}
+
+	feeCap := new(big.Int).Mul(header.BaseFee, big.NewInt(s.feeCapMultiplier))
+	feeCap.Add(feeCap, tip)
+
+	auth.GasTipCap = tip
+	auth.GasFeeCap = feeCap
+	auth.GasPrice = nil
+	return nil
+}
+
+func (s *dynamicFeeStrategy) Bump(auth *bind.TransactOpts) {
+	auth.GasTipCap = bumpByPermille(auth.GasTipCap, resubmitBumpPermille)
+	auth.GasFeeCap = bumpByPermille(auth.GasFeeCap, resubmitBumpPermille)
+}
+
+// autoFeeStrategy prefers dynamicFeeStrategy and falls back to legacyFeeStrategy the first time
+// dynamicFeeStrategy.Apply returns any error (the chain has no base fee, or a fee RPC such as
+// eth_maxPriorityFeePerGas or the latest-header fetch fails), remembering the choice afterwards. A
+// single instance is shared across every concurrent SendAndWait call for an account (see
+// NewXMREscrowClient), so active is guarded by mu rather than assumed to be set under some
+// caller's lock.
+type autoFeeStrategy struct {
+	dynamic FeeStrategy
+	legacy  FeeStrategy
+
+	mu     sync.Mutex
+	active FeeStrategy
+}
+
+// newAutoFeeStrategy returns a FeeStrategy that transparently falls back to legacy pricing on
+// chains without a base fee, so callers don't need to know the chain's fee market in advance.
+func newAutoFeeStrategy() FeeStrategy {
+	return &autoFeeStrategy{
+		dynamic: NewDynamicFeeStrategy(defaultTipFloorGwei),
+		legacy:  NewLegacyFeeStrategy(),
+	}
+}
+
+func (s *autoFeeStrategy) Apply(ctx context.Context, client *ethclient.Client, auth *bind.TransactOpts) error {
+	if active := s.getActive(); active != nil {
+		return active.Apply(ctx, client, auth)
+	}
+
+	if err := s.dynamic.Apply(ctx, client, auth); err == nil {
+		s.setActive(s.dynamic)
+		return nil
+	}
+
+	s.setActive(s.legacy)
+	return s.legacy.Apply(ctx, client, auth)
+}
+
+func (s *autoFeeStrategy) Bump(auth *bind.TransactOpts) {
+	active := s.getActive()
+	if active == nil {
+		active = s.legacy
+		s.setActive(active)
+	}
+	active.Bump(auth)
+}
+
+func (s *autoFeeStrategy) getActive() FeeStrategy {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.active
+}
+
+func (s *autoFeeStrategy) setActive(strategy FeeStrategy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active = strategy
+}
+
+// bumpByPermille returns value increased by at least permille thousandths, or nil if value is nil.
+func bumpByPermille(value *big.Int, permille int64) *big.Int {
+	if value == nil {
+		return nil
+	}
+	bumped := new(big.Int).Mul(value, big.NewInt(1000+permille))
+	bumped.Add(bumped, big.NewInt(999)) // round up so the increase is never below permille
+	return bumped.Div(bumped, big.NewInt(1000))
+}
+
+// bumpGasByPermille returns gas increased by at least permille thousandths.
+func bumpGasByPermille(gas uint64, permille int64) uint64 {
+	bumped := new(big.Int).Mul(new(big.Int).SetUint64(gas), big.NewInt(1000+permille))
+	bumped.Add(bumped, big.NewInt(999)) // round up so the increase is never below permille
+	return bumped.Div(bumped, big.NewInt(1000)).Uint64()
+}
@@ -0,0 +1,113 @@
+package oneinch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+func TestBumpByPermille(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    *big.Int
+		permille int64
+		want     *big.Int
+	}{
+		{"nil value stays nil", nil, resubmitBumpPermille, nil},
+		{"12.5% resubmit bump", big.NewInt(1_000_000_000), resubmitBumpPermille, big.NewInt(1_125_000_000)},
+		{"zero value stays zero", big.NewInt(0), resubmitBumpPermille, big.NewInt(0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bumpByPermille(tt.value, tt.permille)
+			if (got == nil) != (tt.want == nil) {
+				t.Fatalf("bumpByPermille(%v, %d) = %v, want %v", tt.value, tt.permille, got, tt.want)
+			}
+			if got != nil && got.Cmp(tt.want) != 0 {
+				t.Fatalf("bumpByPermille(%v, %d) = %v, want %v", tt.value, tt.permille, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBumpGasByPermille(t *testing.T) {
+	got := bumpGasByPermille(1_000_000, gasLimitHeadroomPermille)
+	want := uint64(1_200_000)
+	if got != want {
+		t.Fatalf("bumpGasByPermille(1_000_000, %d) = %d, want %d", gasLimitHeadroomPermille, got, want)
+	}
+}
+
+// TestAutoFeeStrategyApplyConcurrentIsRaceFree guards against a data race on autoFeeStrategy's
+// active field: TxManager.newAuth calls fees.Apply outside its own lock so independent
+// SendAndWait calls can run concurrently, but every account shares one autoFeeStrategy instance.
+// Run with -race; it fails before this field was moved under autoFeeStrategy's own mutex.
+func TestAutoFeeStrategyApplyConcurrentIsRaceFree(t *testing.T) {
+	server := httptest.NewServer(legacyOnlyFeeRPCHandler(t))
+	defer server.Close()
+
+	client, err := ethclient.Dial(server.URL)
+	if err != nil {
+		t.Fatalf("ethclient.Dial() error = %v", err)
+	}
+	defer client.Close()
+
+	strategy := newAutoFeeStrategy()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			auth := &bind.TransactOpts{}
+			if err := strategy.Apply(context.Background(), client, auth); err != nil {
+				t.Errorf("Apply() error = %v", err)
+				return
+			}
+			strategy.Bump(auth)
+		}()
+	}
+	wg.Wait()
+}
+
+// legacyOnlyFeeRPCHandler serves a fake node that doesn't support the EIP-1559 fee market, so
+// every autoFeeStrategy.Apply call falls back to legacy gas pricing.
+func legacyOnlyFeeRPCHandler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeRPCError(t, w, nil, fmt.Errorf("failed to decode JSON-RPC request: %w", err))
+			return
+		}
+
+		switch req.Method {
+		case "eth_maxPriorityFeePerGas":
+			writeRPCErrorResponse(w, req.ID, fmt.Errorf("method not supported"))
+		case "eth_gasPrice":
+			w.Header().Set("Content-Type", "application/json")
+			resp := map[string]interface{}{
+				"jsonrpc": "2.0",
+				"id":      req.ID,
+				"result":  hexutil.EncodeUint64(1_000_000_000),
+			}
+			if err := json.NewEncoder(w).Encode(resp); err != nil {
+				t.Errorf("failed to encode eth_gasPrice response: %v", err)
+			}
+		default:
+			writeRPCError(t, w, req.ID, fmt.Errorf("unexpected JSON-RPC method %q", req.Method))
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package oneinch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// PrivateTxConfig configures routing of sensitive transactions (most importantly ClaimSwap,
+// which reveals the atomic swap secret and is the highest-value frontrunning target in this
+// protocol) through a private relay such as Flashbots Protect or MEV-Blocker instead of the
+// public mempool.
+type PrivateTxConfig struct {
+	// RelayURL is the JSON-RPC endpoint of the private relay, e.g. an
+	// eth_sendPrivateTransaction-compatible endpoint.
+	RelayURL string
+	// MaxBlockNumber, if set, is the last block the relay should attempt inclusion in before
+	// giving up on the bundle.
+	MaxBlockNumber *big.Int
+	// AllowRevert tells the relay it's acceptable to include the transaction even if it reverts.
+	AllowRevert bool
+	// AllowPublicFallback submits via the public mempool if the relay is unreachable, rather
+	// than failing the send outright.
+	AllowPublicFallback bool
+}
+
+// privateSender submits signed transactions to a private relay via eth_sendPrivateTransaction,
+// keeping them out of the public mempool until they're included.
+type privateSender struct {
+	rpcClient *rpc.Client
+	cfg       PrivateTxConfig
+}
+
+// newPrivateSender dials cfg.RelayURL and returns a privateSender that submits through it.
+func newPrivateSender(cfg PrivateTxConfig) (*privateSender, error) {
+	if cfg.RelayURL == "" {
+		return nil, errors.New("oneinch: private relay URL is required")
+	}
+
+	rpcClient, err := rpc.Dial(cfg.RelayURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial private relay %s: %w", cfg.RelayURL, err)
+	}
+
+	return &privateSender{rpcClient: rpcClient, cfg: cfg}, nil
+}
+
+// Send submits tx's raw signed bytes to the private relay via eth_sendPrivateTransaction, whose
+// single positional parameter is an object of {tx, maxBlockNumber, preferences}.
+func (s *privateSender) Send(ctx context.Context, tx *types.Transaction) error {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode transaction for private relay: %w", err)
+	}
+
+	preferences := map[string]interface{}{
+		"canRevert": s.cfg.AllowRevert,
+	}
+	params := map[string]interface{}{
+		"tx":          hexutil.Encode(raw),
+		"preferences": preferences,
+	}
+	if s.cfg.MaxBlockNumber != nil {
+		params["maxBlockNumber"] = hexutil.EncodeBig(s.cfg.MaxBlockNumber)
+	}
+
+	return s.rpcClient.CallContext(ctx, nil, "eth_sendPrivateTransaction", params)
+}
@@ -0,0 +1,306 @@
+package oneinch
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// resendInterval is how long SendAndWait waits for a transaction to be mined before it rebuilds
+// and resubmits the same nonce with a bumped fee.
+const resendInterval = 15 * time.Second
+
+// receiptPollInterval is how often SendAndWait checks whether a submitted transaction has been
+// mined.
+const receiptPollInterval = 2 * time.Second
+
+// errResendTimeout is returned internally by waitForReceipt when a transaction isn't mined
+// within resendInterval, distinguishing "time to bump and resubmit" from a real ctx cancellation.
+var errResendTimeout = errors.New("oneinch: transaction not mined within resend interval")
+
+// TxBuilder packs, signs, and sends one attempt of a transaction using auth, returning the
+// resulting transaction. It is called again with a fee-bumped auth if the prior attempt stalls.
+type TxBuilder func(auth *bind.TransactOpts) (*types.Transaction, error)
+
+// TxManager serializes nonce assignment for a single account so that CreateSwap, ClaimSwap, and
+// RefundSwap never race over the same pending nonce when multiple swaps are in flight
+// concurrently. Only nonce bookkeeping is serialized: once a nonce is assigned, independent
+// SendAndWait calls build, submit, and wait for receipts concurrently, so a stuck resubmission
+// loop for one swap can never block a time-critical send (e.g. a ClaimSwap racing Timeout1) for
+// another. Both the 1inch adapter path and the direct escrow path should share one TxManager per
+// account.
+type TxManager struct {
+	client  *ethclient.Client
+	privKey *ecdsa.PrivateKey
+	chainID *big.Int
+	fees    FeeStrategy
+
+	mu        sync.Mutex
+	nextNonce *uint64
+
+	// private, when set, routes transactions through a relay instead of the public mempool. See
+	// WithPrivateSubmission. Guarded by mu since it may be read by a SendAndWait in flight.
+	private    *privateSender
+	privateCfg PrivateTxConfig
+}
+
+// NewTxManager creates a TxManager that signs with privKey and prices transactions using fees.
+func NewTxManager(client *ethclient.Client, privKey *ecdsa.PrivateKey, chainID *big.Int, fees FeeStrategy) *TxManager {
+	return &TxManager{
+		client:  client,
+		privKey: privKey,
+		chainID: chainID,
+		fees:    fees,
+	}
+}
+
+// Address returns the account TxManager signs and sends transactions for.
+func (m *TxManager) Address() common.Address {
+	return crypto.PubkeyToAddress(m.privKey.PublicKey)
+}
+
+// WithPrivateSubmission configures m to submit transactions through cfg's private relay instead
+// of the public mempool, falling back to public submission only if cfg.AllowPublicFallback is
+// true and the relay is unreachable.
+func (m *TxManager) WithPrivateSubmission(cfg PrivateTxConfig) error {
+	sender, err := newPrivateSender(cfg)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.private = sender
+	m.privateCfg = cfg
+	return nil
+}
+
+// SendAndWait builds and sends a transaction via buildTx, then polls for its inclusion. If the
+// transaction isn't mined within resendInterval, it rebuilds the same-nonce transaction with a
+// bumped fee via buildTx and resubmits, repeating until it is mined or ctx expires. Only nonce
+// assignment is serialized against other in-flight SendAndWait calls; everything else here runs
+// without holding m.mu, so a stalled resubmission loop for one swap never blocks another swap's
+// send or its ability to respect its own ctx deadline.
+func (m *TxManager) SendAndWait(ctx context.Context, buildTx TxBuilder) (*types.Transaction, *types.Receipt, error) {
+	sender, senderCfg := m.privateConfig()
+
+	auth, nonce, err := m.newAuth(ctx, sender)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Only this first attempt can fail before anything reaches the network, so only it releases
+	// the reserved nonce on failure. A failure from a later resubmission attempt (below) leaves
+	// the nonce reserved, since the initial broadcast already put it in flight on chain.
+	tx, err := m.buildAndSubmit(ctx, auth, buildTx, sender, senderCfg)
+	if err != nil {
+		m.releaseNonce(nonce)
+		return nil, nil, err
+	}
+
+	for {
+		receipt, err := m.waitForReceipt(ctx, tx)
+		if err == nil {
+			return tx, receipt, nil
+		}
+		if !errors.Is(err, errResendTimeout) {
+			return nil, nil, err
+		}
+
+		m.fees.Bump(auth)
+		tx, err = m.buildAndSubmit(ctx, auth, buildTx, sender, senderCfg)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// buildAndSubmit estimates gas, packs/signs one attempt via buildTx, and, if a private relay is
+// configured, submits it there instead of letting bind.BoundContract.Transact broadcast it
+// publicly.
+func (m *TxManager) buildAndSubmit(ctx context.Context, auth *bind.TransactOpts, buildTx TxBuilder, sender *privateSender, senderCfg PrivateTxConfig) (*types.Transaction, error) {
+	gasLimit, err := m.estimateGasLimit(ctx, auth, buildTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate gas: %w", err)
+	}
+	auth.GasLimit = gasLimit
+
+	tx, err := buildTx(auth)
+	if err != nil {
+		return nil, err
+	}
+	if auth.NoSend {
+		if err := m.submitPrivately(ctx, tx, sender, senderCfg); err != nil {
+			return nil, err
+		}
+	}
+	return tx, nil
+}
+
+// estimateGasLimit packs buildTx's call through a throwaway probe auth (a placeholder gas limit
+// so bind.BoundContract.Transact neither sends it nor runs its own implicit estimation), then
+// calls client.EstimateGas itself on the resulting destination/calldata/value. Estimating
+// explicitly, rather than leaving auth.GasLimit at zero for bind to estimate internally, lets us
+// surface a would-revert failure distinctly and apply our own safety multiplier before anything
+// is broadcast.
+func (m *TxManager) estimateGasLimit(ctx context.Context, auth *bind.TransactOpts, buildTx TxBuilder) (uint64, error) {
+	probe := *auth
+	probe.NoSend = true
+	probe.GasLimit = probeGasLimit
+
+	tx, err := buildTx(&probe)
+	if err != nil {
+		return 0, err
+	}
+
+	msg := ethereum.CallMsg{
+		From:      auth.From,
+		To:        tx.To(),
+		Value:     tx.Value(),
+		Data:      tx.Data(),
+		GasFeeCap: tx.GasFeeCap(),
+		GasTipCap: tx.GasTipCap(),
+	}
+	gas, err := m.client.EstimateGas(ctx, msg)
+	if err != nil {
+		return 0, err
+	}
+	return bumpGasByPermille(gas, gasLimitHeadroomPermille), nil
+}
+
+// submitPrivately sends tx through the configured private relay, falling back to the public
+// mempool if the relay is unreachable and private submission allows it.
+func (m *TxManager) submitPrivately(ctx context.Context, tx *types.Transaction, sender *privateSender, cfg PrivateTxConfig) error {
+	err := sender.Send(ctx, tx)
+	if err == nil {
+		return nil
+	}
+	// A JSON-RPC error response means the relay was reached and rejected the transaction, which
+	// is not a case for public fallback.
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return fmt.Errorf("oneinch: private relay rejected the transaction: %w", err)
+	}
+	if !cfg.AllowPublicFallback {
+		return fmt.Errorf("oneinch: private relay submission failed and public fallback is disabled: %w", err)
+	}
+	return m.client.SendTransaction(ctx, tx)
+}
+
+// privateConfig returns a snapshot of the currently configured private relay, guarded by mu since
+// WithPrivateSubmission may run concurrently with an in-flight SendAndWait.
+func (m *TxManager) privateConfig() (*privateSender, PrivateTxConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.private, m.privateCfg
+}
+
+// newAuth builds transaction auth for the next attempt, reserving the next nonce under mu so that
+// concurrent callers of SendAndWait never collide on the same nonce. The lock is held only for
+// this bookkeeping; it is released before the returned auth is used to build, submit, or wait for
+// a transaction.
+func (m *TxManager) newAuth(ctx context.Context, sender *privateSender) (*bind.TransactOpts, uint64, error) {
+	auth, err := bind.NewKeyedTransactorWithChainID(m.privKey, m.chainID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	nonce, err := m.reserveNonce(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	auth.Nonce = new(big.Int).SetUint64(nonce)
+	auth.Value = big.NewInt(0)
+	auth.NoSend = sender != nil
+
+	if err := m.fees.Apply(ctx, m.client, auth); err != nil {
+		m.releaseNonce(nonce)
+		return nil, 0, err
+	}
+
+	return auth, nonce, nil
+}
+
+// reserveNonce assigns the next nonce to use and immediately advances the cached nonce, so two
+// concurrent SendAndWait calls always receive distinct nonces regardless of how long either one's
+// mine-and-resubmit loop takes. A nonce reserved this way must eventually either reach the network
+// (in which case it stays reserved forever, which is correct) or be returned via releaseNonce if
+// the attempt fails before broadcasting, so a single failed call can't permanently wedge every
+// nonce after it.
+func (m *TxManager) reserveNonce(ctx context.Context) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	nonce, err := m.pendingNonceLocked(ctx)
+	if err != nil {
+		return 0, err
+	}
+	next := nonce + 1
+	m.nextNonce = &next
+	return nonce, nil
+}
+
+// releaseNonce undoes the reservation of nonce made by reserveNonce, for a call whose transaction
+// never reached the network (e.g. fee pricing, gas estimation, or building the transaction
+// failed). It only rewinds nextNonce if no other call has reserved a later nonce in the meantime;
+// otherwise that later nonce is left as the account's next one, since rewinding past it would
+// hand the same nonce out twice.
+func (m *TxManager) releaseNonce(nonce uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.nextNonce != nil && *m.nextNonce == nonce+1 {
+		m.nextNonce = &nonce
+	}
+}
+
+// pendingNonceLocked returns the next nonce to use, assuming mu is already held.
+func (m *TxManager) pendingNonceLocked(ctx context.Context) (uint64, error) {
+	if m.nextNonce != nil {
+		return *m.nextNonce, nil
+	}
+
+	addr := crypto.PubkeyToAddress(m.privKey.PublicKey)
+	nonce, err := m.client.PendingNonceAt(ctx, addr)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch pending nonce: %w", err)
+	}
+	return nonce, nil
+}
+
+// waitForReceipt polls for tx's receipt, returning errResendTimeout if it isn't mined within
+// resendInterval so the caller can bump fees and resubmit.
+func (m *TxManager) waitForReceipt(ctx context.Context, tx *types.Transaction) (*types.Receipt, error) {
+	deadline := time.NewTimer(resendInterval)
+	defer deadline.Stop()
+
+	ticker := time.NewTicker(receiptPollInterval)
+	defer ticker.Stop()
+
+	for {
+		receipt, err := m.client.TransactionReceipt(ctx, tx.Hash())
+		if err == nil {
+			return receipt, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline.C:
+			return nil, errResendTimeout
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package oneinch
+
+import (
+	"context"
+	"testing"
+)
+
+// TestReleaseNonceRewindsAfterFailedAttempt guards against a mid-pipeline failure (fee pricing,
+// gas estimation, or building the transaction failing before anything reaches the network)
+// permanently advancing nextNonce past a nonce that was never actually broadcast. Without
+// releaseNonce, the next SendAndWait call would reserve a nonce the node can never include until
+// the gap left by the failed attempt is filled, wedging every subsequent send for the account.
+func TestReleaseNonceRewindsAfterFailedAttempt(t *testing.T) {
+	m := &TxManager{}
+	start := uint64(5)
+	m.nextNonce = &start
+
+	reserved, err := m.reserveNonce(context.Background())
+	if err != nil {
+		t.Fatalf("reserveNonce() error = %v", err)
+	}
+	if reserved != 5 {
+		t.Fatalf("reserveNonce() = %d, want 5", reserved)
+	}
+
+	// Simulate the reserved nonce's attempt failing before it was broadcast.
+	m.releaseNonce(reserved)
+
+	next, err := m.reserveNonce(context.Background())
+	if err != nil {
+		t.Fatalf("reserveNonce() after release error = %v", err)
+	}
+	if next != 5 {
+		t.Fatalf("reserveNonce() after release = %d, want 5 (the failed attempt's nonce, handed out again)", next)
+	}
+}
+
+// TestReleaseNonceNoOpIfLaterNonceAlreadyReserved checks that releasing a stale nonce doesn't
+// rewind nextNonce past a nonce another call has already reserved, which would hand out the same
+// nonce to two in-flight sends.
+func TestReleaseNonceNoOpIfLaterNonceAlreadyReserved(t *testing.T) {
+	m := &TxManager{}
+	start := uint64(5)
+	m.nextNonce = &start
+
+	first, err := m.reserveNonce(context.Background())
+	if err != nil {
+		t.Fatalf("reserveNonce() error = %v", err)
+	}
+	second, err := m.reserveNonce(context.Background())
+	if err != nil {
+		t.Fatalf("reserveNonce() error = %v", err)
+	}
+	if second != first+1 {
+		t.Fatalf("reserveNonce() = %d, want %d", second, first+1)
+	}
+
+	// first's attempt fails after second has already reserved the next nonce; releasing first
+	// must not rewind nextNonce back past second's in-flight reservation.
+	m.releaseNonce(first)
+
+	third, err := m.reserveNonce(context.Background())
+	if err != nil {
+		t.Fatalf("reserveNonce() error = %v", err)
+	}
+	if third != second+1 {
+		t.Fatalf("reserveNonce() after stale release = %d, want %d (second's reservation must stand)", third, second+1)
+	}
+}
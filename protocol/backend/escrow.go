@@ -9,7 +9,14 @@ import (
 	"github.com/athanorlabs/atomic-swap/ethereum/oneinch"
 )
 
-// EscrowClient returns the initialized 1inch escrow client
+// EscrowClient returns the initialized 1inch escrow client. The client itself is a thin
+// dispatcher: it resolves the versioned oneinch.Contractor for each escrow and delegates to it,
+// so an escrow ABI upgrade never requires callers of this method to change.
+//
+// oneinch.XMREscrowClient.WatchSwap delivers typed escrow events in place of polling
+// GetSwapState, but no caller in this package's swap handling wires it in yet - there is no swap
+// FSM in this tree for it to drive. That wiring belongs wherever the actual FSM consumer of this
+// client lives.
 func (b *backend) EscrowClient() (*oneinch.XMREscrowClient, error) {
 	if b.escrowClient == nil {
 		return nil, fmt.Errorf("escrow client not initialized, escrow addresses may not be configured")